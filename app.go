@@ -1,6 +1,7 @@
 package gear
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +10,11 @@ import (
 	"net/http"
 	"net/textproto"
 	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
 )
 
 // Version is Gear's version
@@ -73,6 +79,24 @@ type Gear struct {
 	// ErrorLog specifies an optional logger for app's errors. Default to nil
 	ErrorLog *log.Logger
 	Server   *http.Server
+
+	// HammerTime is the duration Shutdown waits for in-flight requests to
+	// finish before forcibly closing lingering connections. Default to 10s.
+	HammerTime time.Duration
+	// HTTP2 configures the http2.Server used by ListenTLS and ListenH2C.
+	HTTP2 HTTP2Config
+	// CertManager issues and renews certificates for ListenAutoTLS. It is
+	// exposed so callers can set Email, RenewBefore or a custom Cache
+	// before calling ListenAutoTLS.
+	CertManager autocert.Manager
+
+	ln              net.Listener
+	wg              sync.WaitGroup
+	onShutdown      []func()
+	inShutdown      int32
+	grpcServer      *grpc.Server
+	challengeLn     net.Listener
+	challengeServer *http.Server
 }
 
 // New creates an instance of Gear.
@@ -113,17 +137,35 @@ func (g *Gear) Listen(addr string) error {
 	if g.ErrorLog != nil {
 		g.Server.ErrorLog = g.ErrorLog
 	}
-	return g.Server.ListenAndServe()
+
+	ln, err := g.listen(addr)
+	if err != nil {
+		return err
+	}
+	g.ln = ln
+	g.handleSignals()
+	return g.Server.Serve(ln)
 }
 
-// ListenTLS starts the HTTPS server.
+// ListenTLS starts the HTTPS server with native HTTP/2 support configured
+// through Gear.HTTP2.
 func (g *Gear) ListenTLS(addr, certFile, keyFile string) error {
 	g.Server.Addr = addr
 	g.Server.Handler = g.toServeHandler()
 	if g.ErrorLog != nil {
 		g.Server.ErrorLog = g.ErrorLog
 	}
-	return g.Server.ListenAndServeTLS(certFile, keyFile)
+	if err := http2.ConfigureServer(g.Server, g.HTTP2.config()); err != nil {
+		return err
+	}
+
+	ln, err := g.listen(addr)
+	if err != nil {
+		return err
+	}
+	g.ln = ln
+	g.handleSignals()
+	return g.Server.ServeTLS(ln, certFile, keyFile)
 }
 
 // Start starts a non-blocking app instance. It is useful for testing.
@@ -143,6 +185,7 @@ func (g *Gear) Start(addr ...string) *ServerListener {
 	if err != nil {
 		panic(NewAppError(fmt.Sprintf("failed to listen on %v: %v", laddr, err)))
 	}
+	g.ln = l
 
 	c := make(chan error)
 	go func() {
@@ -153,7 +196,7 @@ func (g *Gear) Start(addr ...string) *ServerListener {
 
 // Error writes error to underlayer logging system (ErrorLog).
 func (g *Gear) Error(err error) {
-	if err == nil {
+	if err != nil {
 		if g.ErrorLog != nil {
 			g.ErrorLog.Println(err)
 		} else {
@@ -168,12 +211,21 @@ type serveHandler struct {
 }
 
 func (h *serveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.app.wg.Add(1)
+	defer h.app.wg.Done()
+
 	var err error
 	ctx := h.app.pool.Get().(*Context)
 	ctx.Reset(w, r)
 
 	for _, handle := range h.middleware {
-		if err = handle(ctx); err != nil {
+		select {
+		case <-ctx.Context().Done():
+			err = ctx.Context().Err()
+		default:
+			err = handle(ctx)
+		}
+		if err != nil {
 			break
 		}
 		if ctx.ended || ctx.Res.finished {
@@ -183,6 +235,19 @@ func (h *serveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// set ended to true after app's middleware process
 	ctx.ended = true
 
+	// The client disconnected or otherwise canceled the request; there is
+	// no response to send and nothing went wrong on our end; a deliberate
+	// app deadline (context.DeadlineExceeded, e.g. from ctx.WithTimeout)
+	// is a real application condition and still falls through to OnError
+	// below. Skip OnError/Error logging so client churn doesn't get
+	// counted and logged as a server error.
+	if errors.Is(err, context.Canceled) {
+		ctx.afterHooks = nil
+		ctx.Reset(nil, nil)
+		h.app.pool.Put(ctx)
+		return
+	}
+
 	// process middleware error with OnCtxError
 	if err != nil {
 		if ctxErr := h.app.OnError(ctx, err); ctxErr != nil {
@@ -216,6 +281,10 @@ func (h *serveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // WrapHandler wrap a http.Handler to Gear Middleware
 func WrapHandler(h http.Handler) Middleware {
 	return func(ctx *Context) error {
+		// ctx.Req already carries ctx.Context(), including any deadline
+		// or cancellation installed by ctx.WithTimeout/WithCancel, so the
+		// wrapped Handler sees the same cancellation signal with no need
+		// to clone the request.
 		h.ServeHTTP(ctx.Res, ctx.Req)
 		return nil
 	}
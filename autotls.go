@@ -0,0 +1,75 @@
+package gear
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+)
+
+// ListenAutoTLS starts an HTTPS server whose certificates are obtained and
+// renewed automatically via Let's Encrypt. hostPolicy controls which hosts
+// autocert is willing to fetch certificates for; it is passed straight
+// through to the underlying autocert.Manager. cacheDir is used as an
+// autocert.DirCache, unless CertManager.Cache is set beforehand.
+//
+// A plain HTTP server is started on :80 to answer the ACME HTTP-01
+// challenge and to redirect everything else to HTTPS.
+func (g *Gear) ListenAutoTLS(hostPolicy autocert.HostPolicy, cacheDir string) error {
+	g.CertManager.Prompt = autocert.AcceptTOS
+	g.CertManager.HostPolicy = hostPolicy
+	if g.CertManager.Cache == nil && cacheDir != "" {
+		g.CertManager.Cache = autocert.DirCache(cacheDir)
+	}
+
+	g.Server.Addr = ":https"
+	g.Server.Handler = g.toServeHandler()
+	if g.ErrorLog != nil {
+		g.Server.ErrorLog = g.ErrorLog
+	}
+	if g.Server.TLSConfig == nil {
+		g.Server.TLSConfig = new(tls.Config)
+	}
+	g.Server.TLSConfig.GetCertificate = g.CertManager.GetCertificate
+	if err := http2.ConfigureServer(g.Server, g.HTTP2.config()); err != nil {
+		return err
+	}
+
+	challengeLn, err := g.listenChallenge()
+	if err != nil {
+		return err
+	}
+	g.challengeLn = challengeLn
+	g.challengeServer = &http.Server{Handler: g.httpChallengeHandler()}
+	go g.serveHTTPChallenge()
+
+	ln, err := g.listen(":https")
+	if err != nil {
+		return err
+	}
+	g.ln = ln
+	g.handleSignals()
+	return g.Server.ServeTLS(ln, "", "")
+}
+
+// httpChallengeHandler answers the ACME HTTP-01 challenge, redirecting
+// every other request to its HTTPS equivalent.
+func (g *Gear) httpChallengeHandler() http.Handler {
+	return g.CertManager.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := "https://" + r.Host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	}))
+}
+
+// serveHTTPChallenge runs the ACME HTTP-01 challenge server on the listener
+// opened by ListenAutoTLS. It returns once challengeServer is shut down or
+// closed, which Gear.Shutdown does, including the Shutdown that follows a
+// SIGHUP-triggered Restart, so the listener never outlives the rest of the
+// server's lifecycle.
+func (g *Gear) serveHTTPChallenge() {
+	if err := g.challengeServer.Serve(g.challengeLn); err != nil && err != http.ErrServerClosed {
+		g.Error(NewAppError(fmt.Sprintf("ACME challenge listener: %v", err)))
+	}
+}
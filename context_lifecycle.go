@@ -0,0 +1,30 @@
+package gear
+
+import (
+	"context"
+	"time"
+)
+
+// Context returns the request's context.Context, derived from r.Context().
+// Middleware can use it to detect client disconnects and per-route
+// deadlines set by WithTimeout or WithCancel.
+func (ctx *Context) Context() context.Context {
+	return ctx.Req.Context()
+}
+
+// WithTimeout replaces the context with one that carries a deadline d from
+// now, and returns its CancelFunc. Middleware iteration aborts once the
+// deadline elapses, so downstream handlers see the cancellation too.
+func (ctx *Context) WithTimeout(d time.Duration) context.CancelFunc {
+	c, cancel := context.WithTimeout(ctx.Context(), d)
+	ctx.Req = ctx.Req.WithContext(c)
+	return cancel
+}
+
+// WithCancel replaces the context with one that can be canceled manually,
+// and returns its CancelFunc.
+func (ctx *Context) WithCancel() context.CancelFunc {
+	c, cancel := context.WithCancel(ctx.Context())
+	ctx.Req = ctx.Req.WithContext(c)
+	return cancel
+}
@@ -0,0 +1,175 @@
+package gear
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// listenFDEnv and challengeFDEnv are the environment variables used to pass
+// the inherited listening sockets from a parent Gear process to its
+// restarted child: fd 3 is the main listener, fd 4 the ACME HTTP-01
+// challenge listener started by ListenAutoTLS, when present.
+const (
+	listenFDEnv    = "GEAR_LISTEN_FD"
+	challengeFDEnv = "GEAR_CHALLENGE_LISTEN_FD"
+)
+
+// listen opens the listener for addr, inheriting it from a parent process
+// via GEAR_LISTEN_FD when present (set by Restart), so the child can start
+// serving the same port immediately instead of racing the parent for it.
+func (g *Gear) listen(addr string) (net.Listener, error) {
+	if os.Getenv(listenFDEnv) != "" {
+		return listenFromFD(3)
+	}
+	return net.Listen("tcp", addr)
+}
+
+// listenChallenge opens the ACME HTTP-01 challenge listener on :80,
+// inheriting it from a parent process via GEAR_CHALLENGE_LISTEN_FD when
+// present, so a SIGHUP-triggered Restart doesn't race the parent to rebind
+// the port.
+func (g *Gear) listenChallenge() (net.Listener, error) {
+	if os.Getenv(challengeFDEnv) != "" {
+		return listenFromFD(4)
+	}
+	return net.Listen("tcp", ":http")
+}
+
+func listenFromFD(fd uintptr) (net.Listener, error) {
+	f := os.NewFile(fd, "")
+	ln, err := net.FileListener(f)
+	f.Close()
+	if err != nil {
+		return nil, NewAppError(fmt.Sprintf("failed to inherit listener: %v", err))
+	}
+	return ln, nil
+}
+
+// RegisterOnShutdown registers a function to call on Shutdown. This can be
+// used to gracefully close resources such as database pools that should
+// only be torn down once in-flight requests have finished.
+func (g *Gear) RegisterOnShutdown(f func()) {
+	g.onShutdown = append(g.onShutdown, f)
+}
+
+// Shutdown gracefully shuts down the server without interrupting any
+// active requests. Shutdown stops the listener, waits for in-flight
+// middleware chains to finish, then runs the callbacks registered with
+// RegisterOnShutdown. If ctx expires or HammerTime elapses first, any
+// lingering connections are forcibly closed and Shutdown returns the
+// context's error.
+func (g *Gear) Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&g.inShutdown, 0, 1) {
+		return NewAppError("server is already shutting down")
+	}
+
+	hammer := g.HammerTime
+	if hammer <= 0 {
+		hammer = 10 * time.Second
+	}
+	hammerCtx, cancel := context.WithTimeout(ctx, hammer)
+	defer cancel()
+
+	err := g.Server.Shutdown(hammerCtx)
+	if g.challengeServer != nil {
+		if cErr := g.challengeServer.Shutdown(hammerCtx); err == nil {
+			err = cErr
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-hammerCtx.Done():
+		if err == nil {
+			err = hammerCtx.Err()
+		}
+		// HammerTime elapsed (or ctx was canceled) before every in-flight
+		// request finished on its own; forcibly close what's left.
+		g.Server.Close()
+		if g.challengeServer != nil {
+			g.challengeServer.Close()
+		}
+	}
+
+	for _, f := range g.onShutdown {
+		f()
+	}
+	return err
+}
+
+// Restart re-execs the current binary, passing the listening socket(s) to
+// the child process as inherited file descriptors and environment
+// variables (GEAR_LISTEN_FD for the main listener, GEAR_CHALLENGE_LISTEN_FD
+// for the ACME HTTP-01 challenge listener started by ListenAutoTLS, if
+// any), so the child can immediately begin serving on the same port(s).
+// Restart only starts the child; it does not shut the parent down. On
+// SIGHUP, handleSignals calls Shutdown after a successful Restart so the
+// parent drains its in-flight requests.
+func (g *Gear) Restart() error {
+	tl, ok := g.ln.(*net.TCPListener)
+	if !ok {
+		return NewAppError("restart requires a TCP listener")
+	}
+	f, err := tl.File()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	extraFiles := []*os.File{f}
+	env := append(os.Environ(), listenFDEnv+"=3")
+
+	if cl, ok := g.challengeLn.(*net.TCPListener); ok {
+		cf, err := cl.File()
+		if err != nil {
+			return err
+		}
+		defer cf.Close()
+		extraFiles = append(extraFiles, cf)
+		env = append(env, challengeFDEnv+"=4")
+	}
+
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	cmd.ExtraFiles = extraFiles
+	cmd.Env = env
+	return cmd.Start()
+}
+
+// handleSignals listens for SIGHUP to trigger a zero-downtime Restart, and
+// for SIGINT/SIGTERM to trigger a graceful Shutdown.
+func (g *Gear) handleSignals() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		for sig := range ch {
+			switch sig {
+			case syscall.SIGHUP:
+				if err := g.Restart(); err != nil {
+					g.Error(err)
+					continue
+				}
+				g.Shutdown(context.Background())
+				return
+			default:
+				g.Shutdown(context.Background())
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,58 @@
+package gear
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestListenFromFD(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	defer ln.Close()
+
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("failed to dup listener fd: %v", err)
+	}
+	defer f.Close()
+
+	inherited, err := listenFromFD(f.Fd())
+	if err != nil {
+		t.Fatalf("listenFromFD returned error: %v", err)
+	}
+	defer inherited.Close()
+
+	if inherited.Addr().String() != ln.Addr().String() {
+		t.Fatalf("inherited listener addr = %s, want %s", inherited.Addr(), ln.Addr())
+	}
+}
+
+func TestShutdownHammerTime(t *testing.T) {
+	g := New()
+	g.HammerTime = 50 * time.Millisecond
+
+	// Simulate an in-flight request that never finishes on its own, the
+	// way serveHandler.ServeHTTP tracks active requests in h.app.wg.
+	stuck := make(chan struct{})
+	g.wg.Add(1)
+	go func() {
+		<-stuck
+		g.wg.Done()
+	}()
+	defer close(stuck)
+
+	start := time.Now()
+	err := g.Shutdown(context.Background())
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected Shutdown to report the hammer timeout, got nil error")
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Fatalf("Shutdown took %v to return, want close to HammerTime (%v)", elapsed, g.HammerTime)
+	}
+}
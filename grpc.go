@@ -0,0 +1,80 @@
+package gear
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// UseGRPC registers a *grpc.Server to be served on the same port as the
+// rest of the middleware stack. Requests are dispatched to it whenever
+// ListenMux sees ProtoMajor == 2 and a Content-Type starting with
+// "application/grpc" (e.g. "application/grpc+proto");
+// everything else falls through to the normal Gear middleware chain.
+func (g *Gear) UseGRPC(server *grpc.Server) {
+	g.grpcServer = server
+}
+
+// isGRPCRequest reports whether r should be dispatched to the registered
+// *grpc.Server instead of the middleware chain.
+func isGRPCRequest(r *http.Request) bool {
+	return r.ProtoMajor == 2 &&
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// ListenMux starts a server that multiplexes gRPC, registered with
+// UseGRPC, and the ordinary Gear middleware stack on the same addr. When
+// tlsConfig is non-nil it serves TLS; otherwise it serves cleartext HTTP/2
+// (h2c), since gRPC requires HTTP/2.
+func (g *Gear) ListenMux(addr string, tlsConfig *tls.Config) error {
+	if g.grpcServer == nil {
+		panic(NewAppError("no grpc server registered, call UseGRPC first"))
+	}
+
+	handler := g.toServeHandler()
+	mux := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isGRPCRequest(r) {
+			g.grpcServer.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	g.Server.Addr = addr
+	if g.ErrorLog != nil {
+		g.Server.ErrorLog = g.ErrorLog
+	}
+
+	if tlsConfig != nil {
+		g.Server.TLSConfig = tlsConfig
+		g.Server.Handler = mux
+		if err := http2.ConfigureServer(g.Server, g.HTTP2.config()); err != nil {
+			return err
+		}
+	} else {
+		g.Server.Handler = h2c.NewHandler(mux, g.HTTP2.config())
+	}
+
+	ln, err := g.listen(addr)
+	if err != nil {
+		return err
+	}
+	g.ln = ln
+	g.handleSignals()
+
+	if tlsConfig != nil {
+		return g.Server.ServeTLS(ln, "", "")
+	}
+	return g.Server.Serve(ln)
+}
+
+// WrapGRPCGateway mounts a grpc-gateway JSON transcoder as ordinary Gear
+// middleware, giving a REST facade over the gRPC methods registered on mux.
+func WrapGRPCGateway(mux *runtime.ServeMux) Middleware {
+	return WrapHandler(mux)
+}
@@ -0,0 +1,33 @@
+package gear
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsGRPCRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		protoMajor  int
+		contentType string
+		want        bool
+	}{
+		{"grpc over h2", 2, "application/grpc", true},
+		{"grpc+proto over h2", 2, "application/grpc+proto", true},
+		{"json over h2", 2, "application/json", false},
+		{"grpc content-type over h1", 1, "application/grpc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.ProtoMajor = tt.protoMajor
+			r.Header.Set("Content-Type", tt.contentType)
+
+			if got := isGRPCRequest(r); got != tt.want {
+				t.Errorf("isGRPCRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package gear
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// HTTP2Config defines the tunable settings Gear passes to http2.ConfigureServer.
+// Zero values leave the corresponding golang.org/x/net/http2.Server default in place.
+type HTTP2Config struct {
+	// MaxConcurrentStreams optionally specifies the number of concurrent
+	// streams that each client may have open at a time.
+	MaxConcurrentStreams uint32
+	// MaxReadFrameSize optionally specifies the largest frame this server
+	// is willing to read from a client.
+	MaxReadFrameSize uint32
+	// IdleTimeout is the timeout after which idle clients are closed with
+	// a GOAWAY frame. PING frames are not considered activity for the
+	// purpose of this timeout. Zero means no idle timeout.
+	IdleTimeout time.Duration
+}
+
+// config builds the *http2.Server that ListenTLS and ListenH2C configure
+// the underlying *http.Server with.
+func (c *HTTP2Config) config() *http2.Server {
+	return &http2.Server{
+		MaxConcurrentStreams: c.MaxConcurrentStreams,
+		MaxReadFrameSize:     c.MaxReadFrameSize,
+		IdleTimeout:          c.IdleTimeout,
+	}
+}
+
+// ListenH2C starts a cleartext HTTP/2 (h2c) server on addr. It is meant to
+// run behind an L7 proxy that already terminates TLS, and for gRPC-style
+// clients that speak HTTP/2 without TLS.
+func (g *Gear) ListenH2C(addr string) error {
+	g.Server.Addr = addr
+	g.Server.Handler = h2c.NewHandler(g.toServeHandler(), g.HTTP2.config())
+	if g.ErrorLog != nil {
+		g.Server.ErrorLog = g.ErrorLog
+	}
+
+	ln, err := g.listen(addr)
+	if err != nil {
+		return err
+	}
+	g.ln = ln
+	g.handleSignals()
+	return g.Server.Serve(ln)
+}
+
+// Protocol returns the request's protocol, e.g. "HTTP/1.1" or "HTTP/2.0".
+func (ctx *Context) Protocol() string {
+	return ctx.Req.Proto
+}
+
+// Push sends an HTTP/2 server push to the client, if the underlying
+// connection supports it. On HTTP/1.1 connections, or when the underlying
+// http.ResponseWriter doesn't implement http.Pusher, it returns
+// http.ErrNotSupported.
+func (ctx *Context) Push(target string, opts *http.PushOptions) error {
+	pusher, ok := ctx.Res.rw.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return pusher.Push(target, opts)
+}
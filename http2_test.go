@@ -0,0 +1,32 @@
+package gear
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHTTP2ConfigConfig(t *testing.T) {
+	c := &HTTP2Config{
+		MaxConcurrentStreams: 100,
+		MaxReadFrameSize:     1 << 20,
+		IdleTimeout:          30 * time.Second,
+	}
+
+	s := c.config()
+	if s.MaxConcurrentStreams != c.MaxConcurrentStreams {
+		t.Errorf("MaxConcurrentStreams = %d, want %d", s.MaxConcurrentStreams, c.MaxConcurrentStreams)
+	}
+	if s.MaxReadFrameSize != c.MaxReadFrameSize {
+		t.Errorf("MaxReadFrameSize = %d, want %d", s.MaxReadFrameSize, c.MaxReadFrameSize)
+	}
+	if s.IdleTimeout != c.IdleTimeout {
+		t.Errorf("IdleTimeout = %v, want %v", s.IdleTimeout, c.IdleTimeout)
+	}
+}
+
+func TestHTTP2ConfigConfigZeroValue(t *testing.T) {
+	s := (&HTTP2Config{}).config()
+	if s.MaxConcurrentStreams != 0 || s.MaxReadFrameSize != 0 || s.IdleTimeout != 0 {
+		t.Errorf("zero-value HTTP2Config should leave http2.Server defaults untouched, got %+v", s)
+	}
+}